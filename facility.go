@@ -0,0 +1,68 @@
+package syslog
+
+// Facility is the syslog facility, the bits of the combined PRIVAL above
+// its 3 least significant ones (PRI = Facility*8 + Severity).
+type Facility int
+
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthpriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClockd
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+var facilityNames = map[Facility]string{
+	FacilityKern:     "kern",
+	FacilityUser:     "user",
+	FacilityMail:     "mail",
+	FacilityDaemon:   "daemon",
+	FacilityAuth:     "auth",
+	FacilitySyslog:   "syslog",
+	FacilityLPR:      "lpr",
+	FacilityNews:     "news",
+	FacilityUUCP:     "uucp",
+	FacilityCron:     "cron",
+	FacilityAuthpriv: "authpriv",
+	FacilityFTP:      "ftp",
+	FacilityNTP:      "ntp",
+	FacilityAudit:    "audit",
+	FacilityAlert:    "alert",
+	FacilityClockd:   "clockd",
+	FacilityLocal0:   "local0",
+	FacilityLocal1:   "local1",
+	FacilityLocal2:   "local2",
+	FacilityLocal3:   "local3",
+	FacilityLocal4:   "local4",
+	FacilityLocal5:   "local5",
+	FacilityLocal6:   "local6",
+	FacilityLocal7:   "local7",
+}
+
+// String returns the conventional short name for f, or "unknown" if f isn't
+// one of the defined facilities.
+func (f Facility) String() string {
+	if name, ok := facilityNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
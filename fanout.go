@@ -0,0 +1,36 @@
+package syslog
+
+import "sync"
+
+// FanoutHandler forwards each message to every child handler concurrently
+// and waits for all of them before continuing the chain, so one slow child
+// doesn't serialize behind another. It always returns m unchanged (or nil,
+// for the nil passed on Server.Shutdown) so handlers after it in the chain
+// still run. Each child is called through Server.safeHandle, so a panic in
+// one child is recovered, logged and counted in Server.HandlerPanics the
+// same as it would be on the synchronous chain.
+type FanoutHandler struct {
+	s        *Server
+	children []Handler
+}
+
+// NewFanoutHandler builds a FanoutHandler that dispatches to each of
+// children concurrently. s is used to recover and count panics from
+// children the same way the rest of the handler chain does.
+func NewFanoutHandler(s *Server, children ...Handler) *FanoutHandler {
+	return &FanoutHandler{s: s, children: children}
+}
+
+func (f *FanoutHandler) Handle(m *Message) *Message {
+	var wg sync.WaitGroup
+	wg.Add(len(f.children))
+	for _, h := range f.children {
+		h := h
+		go func() {
+			defer wg.Done()
+			f.s.safeHandle(h, m)
+		}()
+	}
+	wg.Wait()
+	return m
+}
@@ -0,0 +1,74 @@
+package syslog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// testFatalLogger satisfies FatalLogger without calling os.Exit, so tests
+// that deliberately trigger a FatalLogger call (or just need a Server
+// that won't crash the test binary) can observe it safely.
+type testFatalLogger struct{ t *testing.T }
+
+func (l *testFatalLogger) Fatalln(v ...interface{}) { l.t.Log(v...) }
+
+type recordingHandler struct {
+	mu  sync.Mutex
+	got []*Message
+}
+
+func (h *recordingHandler) Handle(m *Message) *Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.got = append(h.got, m)
+	return m
+}
+
+func (h *recordingHandler) messages() []*Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*Message(nil), h.got...)
+}
+
+type panicHandler struct{}
+
+func (panicHandler) Handle(m *Message) *Message { panic("boom") }
+
+type sleepHandler struct{ d time.Duration }
+
+func (h sleepHandler) Handle(m *Message) *Message {
+	time.Sleep(h.d)
+	return m
+}
+
+func TestFanoutHandlerRecoversPanickingChild(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	fo := NewFanoutHandler(s, panicHandler{}, rec)
+
+	result := fo.Handle(&Message{Content: "hello"})
+	if result == nil || result.Content != "hello" {
+		t.Errorf("Handle returned %+v, want original message unchanged", result)
+	}
+	if got := s.HandlerPanics(); got != 1 {
+		t.Errorf("HandlerPanics() = %d, want 1", got)
+	}
+	if got := rec.messages(); len(got) != 1 {
+		t.Fatalf("non-panicking child ran %d times, want 1 (it must not be affected by its sibling panicking)", len(got))
+	}
+}
+
+func TestFanoutHandlerRunsChildrenConcurrently(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	const delay = 50 * time.Millisecond
+	fo := NewFanoutHandler(s, sleepHandler{delay}, sleepHandler{delay}, sleepHandler{delay})
+
+	start := time.Now()
+	fo.Handle(&Message{})
+	if elapsed := time.Since(start); elapsed > 2*delay {
+		t.Errorf("Handle took %v, want ~%v if children run concurrently", elapsed, delay)
+	}
+}
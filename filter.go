@@ -0,0 +1,74 @@
+package syslog
+
+import (
+	"path"
+	"regexp"
+)
+
+// FilterHandler drops messages that don't satisfy match, counting each
+// drop in Server.DroppedByFilter. A match returning true lets the message
+// continue to the next handler in the chain unchanged.
+type FilterHandler struct {
+	s     *Server
+	match func(*Message) bool
+}
+
+// NewFilterHandler builds a FilterHandler from an arbitrary predicate. The
+// FilterByFacility, FilterBySeverity, FilterByHostnameGlob and
+// FilterByTagRegexp constructors cover the common cases.
+func NewFilterHandler(s *Server, match func(*Message) bool) *FilterHandler {
+	return &FilterHandler{s: s, match: match}
+}
+
+// FilterByFacility passes only messages whose Facility is one of facilities.
+func FilterByFacility(s *Server, facilities ...Facility) *FilterHandler {
+	allowed := make(map[Facility]bool, len(facilities))
+	for _, f := range facilities {
+		allowed[f] = true
+	}
+	return NewFilterHandler(s, func(m *Message) bool {
+		return allowed[m.Facility]
+	})
+}
+
+// FilterBySeverity passes only messages at least as severe as max (lower
+// Severity values are more severe, per RFC 5424).
+func FilterBySeverity(s *Server, max Severity) *FilterHandler {
+	return NewFilterHandler(s, func(m *Message) bool {
+		return m.Severity <= max
+	})
+}
+
+// FilterByHostnameGlob passes only messages whose Hostname matches the
+// shell glob pattern (path.Match syntax).
+func FilterByHostnameGlob(s *Server, pattern string) (*FilterHandler, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return NewFilterHandler(s, func(m *Message) bool {
+		ok, _ := path.Match(pattern, m.Hostname)
+		return ok
+	}), nil
+}
+
+// FilterByTagRegexp passes only messages whose Tag matches expr.
+func FilterByTagRegexp(s *Server, expr string) (*FilterHandler, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return NewFilterHandler(s, func(m *Message) bool {
+		return re.MatchString(m.Tag)
+	}), nil
+}
+
+func (f *FilterHandler) Handle(m *Message) *Message {
+	if m == nil {
+		return nil
+	}
+	if f.match(m) {
+		return m
+	}
+	f.s.addDroppedByFilter(1)
+	return nil
+}
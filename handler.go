@@ -0,0 +1,13 @@
+package syslog
+
+// Handler processes a parsed Message. It returns the Message to pass it on
+// to the next handler in the chain, or nil to stop propagation (for example
+// after a handler that filters messages out).
+//
+// Handle(nil) is passed down the handler chain once, right before a Server
+// shuts down, so handlers that hold resources (open files, network
+// connections) know to flush and close them. Like any other message it
+// goes no further than the first handler that returns nil.
+type Handler interface {
+	Handle(m *Message) *Message
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chrissnell/syslog"
+)
+
+// FileHandler writes one line per message to a file, rotating it before a
+// write that would take it past maxSize bytes or once it has been open
+// longer than maxAge (either limit of zero disables that trigger),
+// optionally gzipping the rotated segment.
+type FileHandler struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	gzip    bool
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time
+}
+
+// NewFileHandler opens path (creating it if necessary) and returns a
+// FileHandler ready to receive messages.
+func NewFileHandler(path string, maxSize int64, maxAge time.Duration, gzipRotated bool) (*FileHandler, error) {
+	h := &FileHandler{path: path, maxSize: maxSize, maxAge: maxAge, gzip: gzipRotated}
+	if err := h.open(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *FileHandler) open() error {
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	h.f = f
+	h.size = info.Size()
+	h.opened = time.Now()
+	return nil
+}
+
+func (h *FileHandler) Handle(m *syslog.Message) *syslog.Message {
+	if m == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.f.Close()
+		return nil
+	}
+
+	line := []byte(fmt.Sprintf("%s %s %s: %s\n", m.Timestamp.Format(time.RFC3339), m.Hostname, m.Tag, m.Content))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shouldRotate(len(line)) {
+		if err := h.rotate(); err != nil {
+			log.Printf("syslog/handlers: rotate %s: %v", h.path, err)
+		}
+	}
+
+	n, err := h.f.Write(line)
+	h.size += int64(n)
+	if err != nil {
+		log.Printf("syslog/handlers: write %s: %v", h.path, err)
+	}
+	return m
+}
+
+func (h *FileHandler) shouldRotate(nextLine int) bool {
+	if h.maxSize > 0 && h.size+int64(nextLine) > h.maxSize {
+		return true
+	}
+	if h.maxAge > 0 && time.Since(h.opened) > h.maxAge {
+		return true
+	}
+	return false
+}
+
+// rotate renames the current file aside and reopens h.path as a fresh
+// file. If renaming fails, h.path is still reopened so h.f stays usable:
+// without that, a single transient rename error would leave h.f a closed
+// *os.File and silently drop every message for the rest of the handler's
+// life. If closing or reopening the file fails, h.f is left closed.
+func (h *FileHandler) rotate() error {
+	if err := h.f.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%s", h.path, time.Now().Format("20060102T150405"))
+	renameErr := os.Rename(h.path, rotated)
+	if renameErr == nil && h.gzip {
+		go gzipAndRemove(rotated)
+	}
+	if err := h.open(); err != nil {
+		return err
+	}
+	return renameErr
+}
+
+func gzipAndRemove(path string) {
+	in, err := os.Open(path)
+	if err != nil {
+		log.Printf("syslog/handlers: gzip open %s: %v", path, err)
+		return
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		log.Printf("syslog/handlers: gzip create %s: %v", path, err)
+		return
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	if _, err := io.Copy(gw, in); err != nil {
+		log.Printf("syslog/handlers: gzip write %s: %v", path, err)
+		return
+	}
+	if err := gw.Close(); err != nil {
+		log.Printf("syslog/handlers: gzip close %s: %v", path, err)
+		return
+	}
+	os.Remove(path)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chrissnell/syslog"
+)
+
+func TestFileHandlerWritesOneLinePerMessage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	h, err := NewFileHandler(path, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Handle(&syslog.Message{Hostname: "mymachine", Tag: "su", Content: ": failure"})
+	h.Handle(nil)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(got), "\n"), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], "mymachine su: : failure") {
+		t.Errorf("file contents = %q", got)
+	}
+}
+
+func TestFileHandlerRotatesOnMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	// maxSize is sized so the first (short) message fits but the second
+	// (long) one doesn't, forcing exactly one rotation.
+	h, err := NewFileHandler(path, 60, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Handle(&syslog.Message{Hostname: "a", Tag: "t", Content: "first"})
+	h.Handle(&syslog.Message{Hostname: "a", Tag: "t", Content: strings.Repeat("x", 50)})
+	h.Handle(nil)
+
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("got %d rotated file(s), want 1: %v", len(matches), matches)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), strings.Repeat("x", 50)) {
+		t.Errorf("current file = %q, want it to contain the post-rotation message", got)
+	}
+}
+
+func TestFileHandlerRotateSurvivesRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.log")
+	h, err := NewFileHandler(path, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the rotated name itself a directory so os.Rename(h.path,
+	// rotated) is guaranteed to fail, without relying on filesystem
+	// permissions (which root ignores).
+	rotated := path + "." + time.Now().Format("20060102T150405")
+	if err := os.Mkdir(rotated, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.rotate(); err == nil {
+		t.Fatal("expected rotate to report the rename failure")
+	}
+
+	// h.f must still be usable after a failed rotate, not left as a
+	// closed *os.File.
+	h.Handle(&syslog.Message{Hostname: "a", Tag: "t", Content: "after-failed-rotate"})
+	h.Handle(nil)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "after-failed-rotate") {
+		t.Errorf("file contents = %q, want the write after a failed rotate to have succeeded", got)
+	}
+}
+
+func TestFileHandlerGzipsRotatedSegment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	// maxSize is sized so the first (short) message fits but the second
+	// (long) one doesn't, forcing exactly one rotation.
+	h, err := NewFileHandler(path, 60, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Handle(&syslog.Message{Hostname: "a", Tag: "t", Content: "first"})
+	h.Handle(&syslog.Message{Hostname: "a", Tag: "t", Content: strings.Repeat("x", 50)})
+	h.Handle(nil)
+
+	// gzipAndRemove runs asynchronously and only os.Remove()s the
+	// uncompressed rotated file once the .gz is fully written, so wait
+	// for the uncompressed file to disappear rather than racing the
+	// .gz's creation against its contents being written.
+	var gzPath string
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		rotated, _ := filepath.Glob(path + ".*")
+		plain := rotated[:0]
+		for _, m := range rotated {
+			if !strings.HasSuffix(m, ".gz") {
+				plain = append(plain, m)
+			}
+		}
+		if len(plain) == 0 {
+			matches, _ := filepath.Glob(path + ".*.gz")
+			if len(matches) == 1 {
+				gzPath = matches[0]
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if gzPath == "" {
+		t.Fatal("timed out waiting for the rotated segment to be gzipped")
+	}
+
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gr.Close()
+	content, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "first") {
+		t.Errorf("gzipped rotated segment = %q, want it to contain the pre-rotation message", content)
+	}
+
+	if _, err := os.Stat(strings.TrimSuffix(gzPath, ".gz")); !os.IsNotExist(err) {
+		t.Errorf("uncompressed rotated file still exists after gzipping: err = %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/chrissnell/syslog"
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusHandler forwards each Message to a logrus.FieldLogger, mapping
+// Severity onto the nearest logrus level (emerg/alert/crit/err to Error,
+// warning to Warn, notice/info to Info, debug to Debug) and emitting
+// Facility, Hostname, Tag and any parsed StructuredData as structured
+// fields. Severity never maps to logrus's Fatal or Panic levels: a
+// Message's Severity comes from an untrusted remote sender, and logrus's
+// default Fatal calls os.Exit, which would let any sender kill the
+// process with a single emerg-priority packet.
+type LogrusHandler struct {
+	log logrus.FieldLogger
+}
+
+// NewLogrusHandler builds a LogrusHandler that logs through log.
+func NewLogrusHandler(log logrus.FieldLogger) *LogrusHandler {
+	return &LogrusHandler{log: log}
+}
+
+func (h *LogrusHandler) Handle(m *syslog.Message) *syslog.Message {
+	if m == nil {
+		return nil
+	}
+
+	fields := logrus.Fields{
+		"facility": m.Facility.String(),
+		"hostname": m.Hostname,
+		"tag":      m.Tag,
+	}
+	for sdID, params := range m.StructuredData {
+		for name, value := range params {
+			fields[sdID+"."+name] = value
+		}
+	}
+	entry := h.log.WithFields(fields)
+
+	switch m.Severity {
+	case syslog.SeverityEmerg, syslog.SeverityAlert, syslog.SeverityCrit, syslog.SeverityErr:
+		entry.Error(m.Content)
+	case syslog.SeverityWarning:
+		entry.Warn(m.Content)
+	case syslog.SeverityNotice, syslog.SeverityInfo:
+		entry.Info(m.Content)
+	case syslog.SeverityDebug:
+		entry.Debug(m.Content)
+	}
+
+	return m
+}
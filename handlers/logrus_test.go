@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chrissnell/syslog"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogrusHandlerNeverCallsFatalOrPanicLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, DisableColors: true})
+
+	exited := false
+	log.ExitFunc = func(int) { exited = true }
+
+	h := NewLogrusHandler(log)
+
+	for _, sev := range []syslog.Severity{syslog.SeverityEmerg, syslog.SeverityAlert, syslog.SeverityCrit} {
+		h.Handle(&syslog.Message{Severity: sev, Content: "boom"})
+	}
+
+	if exited {
+		t.Fatal("LogrusHandler triggered the logger's ExitFunc for an emerg/alert/crit message; an untrusted remote sender must never be able to halt the process")
+	}
+	if n := strings.Count(buf.String(), "level=error"); n != 3 {
+		t.Errorf("got %d error-level log lines, want 3 (one per emerg/alert/crit message)", n)
+	}
+}
+
+func TestLogrusHandlerMapsSeverityToLevel(t *testing.T) {
+	cases := []struct {
+		sev  syslog.Severity
+		want string
+	}{
+		{syslog.SeverityErr, "level=error"},
+		{syslog.SeverityWarning, "level=warning"},
+		{syslog.SeverityNotice, "level=info"},
+		{syslog.SeverityInfo, "level=info"},
+		{syslog.SeverityDebug, "level=debug"},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		log := logrus.New()
+		log.SetOutput(&buf)
+		log.SetLevel(logrus.DebugLevel)
+		log.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, DisableColors: true})
+
+		NewLogrusHandler(log).Handle(&syslog.Message{Severity: c.sev, Content: "hi"})
+
+		if !strings.Contains(buf.String(), c.want) {
+			t.Errorf("severity %v: got %q, want it to contain %q", c.sev, buf.String(), c.want)
+		}
+	}
+}
+
+func TestLogrusHandlerSetsStructuredFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, DisableColors: true})
+
+	h := NewLogrusHandler(log)
+	h.Handle(&syslog.Message{
+		Severity: syslog.SeverityInfo,
+		Hostname: "mymachine",
+		Tag:      "su",
+		Content:  "hello",
+		StructuredData: map[string]map[string]string{
+			"exampleSDID@0": {"eventID": "1011"},
+		},
+	})
+
+	out := buf.String()
+	for _, want := range []string{`hostname=mymachine`, `tag=su`, `exampleSDID@0.eventID=1011`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestLogrusHandlerIgnoresNilShutdownSignal(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+
+	h := NewLogrusHandler(log)
+	if got := h.Handle(nil); got != nil {
+		t.Errorf("Handle(nil) = %v, want nil", got)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no log output for a nil shutdown signal, got %q", buf.String())
+	}
+}
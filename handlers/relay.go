@@ -0,0 +1,163 @@
+// Package handlers provides concrete syslog.Handler implementations for
+// the deployments this server is commonly dropped into: relaying to an
+// upstream syslog server, writing to a rotated file, and bridging into
+// logrus.
+package handlers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chrissnell/syslog"
+)
+
+// RelayProto selects the wire transport RelayHandler uses to reach its
+// upstream syslog server.
+type RelayProto string
+
+const (
+	RelayUDP RelayProto = "udp"
+	RelayTCP RelayProto = "tcp"
+	RelayTLS RelayProto = "tls"
+)
+
+// RelayHandler re-emits every Message it handles to an upstream syslog
+// server, mirroring what a syslog client would send. Over UDP each
+// message is one datagram; over TCP and TLS messages are framed with
+// RFC 6587 octet-counting.
+type RelayHandler struct {
+	addr      string
+	proto     RelayProto
+	tlsConfig *tls.Config
+	rfc5424   bool
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewRelayHandler builds a RelayHandler that dials addr lazily, on first
+// use, and redials after any write failure. If rfc5424 is true, messages
+// are re-rendered as RFC 5424 (preserving STRUCTURED-DATA); otherwise they
+// are re-rendered in the original RFC 3164 wire form. tlsConfig is only
+// used when proto is RelayTLS.
+func NewRelayHandler(proto RelayProto, addr string, rfc5424 bool, tlsConfig *tls.Config) *RelayHandler {
+	return &RelayHandler{addr: addr, proto: proto, rfc5424: rfc5424, tlsConfig: tlsConfig}
+}
+
+func (h *RelayHandler) Handle(m *syslog.Message) *syslog.Message {
+	if m == nil {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if h.conn != nil {
+			h.conn.Close()
+			h.conn = nil
+		}
+		return nil
+	}
+
+	var line []byte
+	if h.rfc5424 {
+		line = renderRFC5424(m)
+	} else {
+		line = renderRFC3164(m)
+	}
+	if err := h.send(line); err != nil {
+		log.Printf("syslog/handlers: relay to %s failed: %v", h.addr, err)
+	}
+	return m
+}
+
+func (h *RelayHandler) send(line []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		conn, err := h.dial()
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+	}
+
+	frame := line
+	if h.proto != RelayUDP {
+		frame = append([]byte(fmt.Sprintf("%d ", len(line))), line...)
+	}
+	if _, err := h.conn.Write(frame); err != nil {
+		h.conn.Close()
+		h.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (h *RelayHandler) dial() (net.Conn, error) {
+	switch h.proto {
+	case RelayTLS:
+		return tls.Dial("tcp", h.addr, h.tlsConfig)
+	case RelayTCP:
+		return net.Dial("tcp", h.addr)
+	default:
+		return net.Dial("udp", h.addr)
+	}
+}
+
+// renderRFC3164 re-renders m in the classic "<prio>Jan _2 15:04:05 host
+// tag: content" wire form.
+func renderRFC3164(m *syslog.Message) []byte {
+	prio := int(m.Facility)*8 + int(m.Severity)
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return []byte(fmt.Sprintf("<%d>%s %s %s: %s", prio, ts.Format("Jan _2 15:04:05"), m.Hostname, m.Tag, m.Content))
+}
+
+// renderRFC5424 re-renders m as an RFC 5424 SYSLOG-MSG, preserving
+// StructuredData.
+func renderRFC5424(m *syslog.Message) []byte {
+	prio := int(m.Facility)*8 + int(m.Severity)
+
+	ts := "-"
+	if !m.Timestamp.IsZero() {
+		ts = m.Timestamp.Format(time.RFC3339Nano)
+	}
+
+	appName := nilValue(m.AppName)
+	if appName == "-" {
+		appName = nilValue(m.Tag)
+	}
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s",
+		prio, ts, nilValue(m.Hostname), appName, nilValue(m.ProcID), nilValue(m.MsgID),
+		renderStructuredData(m.StructuredData), m.Content))
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func renderStructuredData(sd map[string]map[string]string) string {
+	if len(sd) == 0 {
+		return "-"
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `]`, `\]`, `"`, `\"`)
+	var b strings.Builder
+	for sdID, params := range sd {
+		b.WriteByte('[')
+		b.WriteString(sdID)
+		for name, value := range params {
+			fmt.Fprintf(&b, ` %s="%s"`, name, escaper.Replace(value))
+		}
+		b.WriteByte(']')
+	}
+	return b.String()
+}
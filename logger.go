@@ -0,0 +1,7 @@
+package syslog
+
+// FatalLogger is the interface Server uses to report errors it cannot
+// recover from. The standard library *log.Logger satisfies it.
+type FatalLogger interface {
+	Fatalln(v ...interface{})
+}
@@ -0,0 +1,48 @@
+package syslog
+
+import (
+	"net"
+	"time"
+)
+
+// Message is a single parsed syslog entry, populated by either the legacy
+// RFC 3164 path or the RFC 5424 path in parsePacket().
+type Message struct {
+	Source   net.Addr
+	Time     time.Time
+	Severity Severity
+	Facility Facility
+
+	Timestamp time.Time
+	Hostname  string
+
+	// Tag/Content hold the legacy RFC 3164 split of the MSG part: Tag is
+	// the leading alphanumeric program name, Content is everything after.
+	// For RFC 5424 messages Tag holds APP-NAME and Content holds MSG.
+	Tag     string
+	Content string
+
+	// Tag1/Content1 are a looser, whitespace-delimited split of the same
+	// MSG part, kept alongside Tag/Content for handlers that prefer it.
+	// They're left empty for RFC 5424 messages.
+	Tag1     string
+	Content1 string
+
+	// Version, AppName, ProcID and MsgID are populated only for RFC 5424
+	// messages; they're left at their zero value for RFC 3164 messages.
+	Version int
+	AppName string
+	ProcID  string
+	MsgID   string
+
+	// StructuredData holds the parsed STRUCTURED-DATA of an RFC 5424
+	// message, keyed by SD-ID and then by parameter name. It's nil for
+	// RFC 3164 messages and for RFC 5424 messages that used NILVALUE.
+	StructuredData map[string]map[string]string
+
+	// PeerIdentity is the subject of the TLS client certificate presented
+	// over a ListenTLS connection, so handlers can authorize per-sender.
+	// It's empty for messages received over any other transport, or over
+	// TLS without a verified client certificate.
+	PeerIdentity string
+}
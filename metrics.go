@@ -0,0 +1,47 @@
+package syslog
+
+import "sync/atomic"
+
+// serverMetrics holds the counters exposed on Server for Prometheus
+// scraping. All fields are accessed only through atomic operations so they
+// can be read from a collector goroutine while the server is running.
+type serverMetrics struct {
+	received              int64
+	droppedByFilter       int64
+	droppedByBackpressure int64
+	handlerPanics         int64
+}
+
+// Received returns the number of messages successfully parsed and handed
+// to the handler chain since the server started.
+func (s *Server) Received() int64 {
+	return atomic.LoadInt64(&s.metrics.received)
+}
+
+// DroppedByFilter returns the number of messages a FilterHandler has
+// rejected.
+func (s *Server) DroppedByFilter() int64 {
+	return atomic.LoadInt64(&s.metrics.droppedByFilter)
+}
+
+// DroppedByBackpressure returns the number of messages discarded because a
+// Pipeline's queue was full or a RateLimitHandler's bucket was empty.
+func (s *Server) DroppedByBackpressure() int64 {
+	return atomic.LoadInt64(&s.metrics.droppedByBackpressure)
+}
+
+// HandlerPanics returns the number of panics recovered from handler Handle
+// calls.
+func (s *Server) HandlerPanics() int64 {
+	return atomic.LoadInt64(&s.metrics.handlerPanics)
+}
+
+func (s *Server) addReceived(n int64) { atomic.AddInt64(&s.metrics.received, n) }
+
+func (s *Server) addDroppedByFilter(n int64) { atomic.AddInt64(&s.metrics.droppedByFilter, n) }
+
+func (s *Server) addDroppedByBackpressure(n int64) {
+	atomic.AddInt64(&s.metrics.droppedByBackpressure, n)
+}
+
+func (s *Server) addHandlerPanics(n int64) { atomic.AddInt64(&s.metrics.handlerPanics, n) }
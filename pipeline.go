@@ -0,0 +1,67 @@
+package syslog
+
+// Pipeline decouples message receipt from handler execution. It is itself
+// a Handler, so it's installed with Server.AddHandler like any other: the
+// receiver goroutine only enqueues onto Pipeline's bounded channel, and a
+// fixed pool of worker goroutines drains the queue and runs Pipeline's own
+// handler chain, so a slow or panicking handler never blocks packet
+// intake. When the queue is full, the message is dropped and counted in
+// Server.DroppedByBackpressure instead of blocking the receiver.
+type Pipeline struct {
+	s        *Server
+	handlers []Handler
+	queue    chan *Message
+}
+
+// NewPipeline creates a Pipeline that buffers up to bufferSize messages and
+// processes them with workers worker goroutines. s is used to record the
+// dropped-by-backpressure and handler-panic counters exposed on Server.
+func NewPipeline(s *Server, bufferSize, workers int) *Pipeline {
+	p := &Pipeline{
+		s:     s,
+		queue: make(chan *Message, bufferSize),
+	}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// AddHandler appends h to the Pipeline's own internal handler chain, run
+// on a worker goroutine rather than the receiver goroutine.
+func (p *Pipeline) AddHandler(h Handler) {
+	p.handlers = append(p.handlers, h)
+}
+
+func (p *Pipeline) worker() {
+	for m := range p.queue {
+		for _, h := range p.handlers {
+			m = p.s.safeHandle(h, m)
+			if m == nil {
+				break
+			}
+		}
+	}
+}
+
+// Handle implements Handler. A nil m (sent on Server.Shutdown) is enqueued
+// like any other message so whichever worker dequeues it runs the
+// Pipeline's handler chain with m == nil, same as the synchronous chain
+// does on shutdown; the queue is then closed so all workers drain and
+// exit once it's been delivered. Any other m is enqueued for asynchronous
+// processing and always stops the synchronous handler chain (by returning
+// nil) since downstream handling now happens on a worker goroutine
+// instead.
+func (p *Pipeline) Handle(m *Message) *Message {
+	if m == nil {
+		p.queue <- nil
+		close(p.queue)
+		return nil
+	}
+	select {
+	case p.queue <- m:
+	default:
+		p.s.addDroppedByBackpressure(1)
+	}
+	return nil
+}
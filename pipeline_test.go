@@ -0,0 +1,89 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+)
+
+type blockingHandler struct{ block <-chan struct{} }
+
+func (h blockingHandler) Handle(m *Message) *Message {
+	if m == nil {
+		return nil
+	}
+	<-h.block
+	return m
+}
+
+func TestPipelineDeliversMessagesAsynchronously(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	p := NewPipeline(s, 4, 1)
+	p.AddHandler(rec)
+
+	for i := 0; i < 3; i++ {
+		p.Handle(&Message{Content: "m"})
+	}
+	p.Handle(nil)
+
+	waitFor(t, func() bool { return len(rec.messages()) == 4 }, "pipeline delivered all 3 messages plus the shutdown signal")
+}
+
+func TestPipelineDropsOnBackpressure(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	block := make(chan struct{})
+	p := NewPipeline(s, 1, 1) // one worker, one slot of buffering
+	p.AddHandler(blockingHandler{block: block})
+
+	p.Handle(&Message{}) // picked up by the sole worker, which blocks on <-block
+	time.Sleep(20 * time.Millisecond)
+	p.Handle(&Message{}) // fills the one buffered slot
+	time.Sleep(20 * time.Millisecond)
+	p.Handle(&Message{}) // queue full: must be dropped, not block the caller
+
+	close(block)
+	p.Handle(nil)
+
+	if got := s.DroppedByBackpressure(); got == 0 {
+		t.Errorf("DroppedByBackpressure() = %d, want > 0", got)
+	}
+}
+
+func TestPipelineShutdownSignalDeliveredExactlyOnce(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	p := NewPipeline(s, 4, 3) // multiple workers racing to read the queue
+	p.AddHandler(rec)
+
+	p.Handle(&Message{Content: "m"})
+	p.Handle(nil)
+
+	waitFor(t, func() bool { return len(rec.messages()) >= 2 }, "pipeline delivered the message and the shutdown signal")
+
+	nilCount := 0
+	for _, m := range rec.messages() {
+		if m == nil {
+			nilCount++
+		}
+	}
+	if nilCount != 1 {
+		t.Errorf("handler saw %d nil shutdown signals, want exactly 1", nilCount)
+	}
+}
+
+// waitFor polls cond until it's true or a short deadline passes, failing
+// the test otherwise. It exists because Pipeline delivery is asynchronous
+// by design, so tests can't assert on it immediately after Handle returns.
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for: %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
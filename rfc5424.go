@@ -0,0 +1,166 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// rfc5424BOM is the UTF-8 byte order mark that RFC 5424 permits (and
+// recommends) immediately before MSG.
+var rfc5424BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// looksLikeRFC5424 reports whether pkt (the packet with any PRI already
+// stripped) starts with an RFC 5424 VERSION field, i.e. its first byte is
+// an ASCII digit. The rest of the header is only checked by parseRFC5424.
+func looksLikeRFC5424(pkt []byte) bool {
+	return len(pkt) > 0 && pkt[0] >= '0' && pkt[0] <= '9'
+}
+
+// parseRFC5424 parses pkt (PRI already stripped) as an RFC 5424
+// SYSLOG-MSG: VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP
+// MSGID SP STRUCTURED-DATA [SP MSG]. It returns a Message with every
+// RFC 5424-specific field populated; Severity/Facility/Source/Time are
+// left for the caller to fill in.
+func parseRFC5424(pkt []byte) (*Message, error) {
+	rest := pkt
+	fields := make([][]byte, 0, 6)
+	for i := 0; i < 6; i++ {
+		sp := bytes.IndexByte(rest, ' ')
+		if sp == -1 {
+			return nil, errors.New("rfc5424: truncated header")
+		}
+		fields = append(fields, rest[:sp])
+		rest = rest[sp+1:]
+	}
+
+	version := 0
+	if _, err := fmt.Sscanf(string(fields[0]), "%d", &version); err != nil {
+		return nil, fmt.Errorf("rfc5424: invalid version %q", fields[0])
+	}
+
+	timestamp, err := parseRFC5424Timestamp(string(fields[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	sd, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return nil, err
+	}
+	rest = bytes.TrimPrefix(rest, rfc5424BOM)
+
+	appName := rfc5424Value(fields[3])
+	m := &Message{
+		Version:        version,
+		Timestamp:      timestamp,
+		Hostname:       rfc5424Value(fields[2]),
+		AppName:        appName,
+		ProcID:         rfc5424Value(fields[4]),
+		MsgID:          rfc5424Value(fields[5]),
+		StructuredData: sd,
+		Content:        string(rest),
+		// Populate legacy Tag from APP-NAME so existing RFC 3164-style
+		// handlers keep working unchanged.
+		Tag: appName,
+	}
+	return m, nil
+}
+
+// rfc5424Value turns the RFC 5424 NILVALUE "-" into an empty string.
+func rfc5424Value(field []byte) string {
+	if len(field) == 1 && field[0] == '-' {
+		return ""
+	}
+	return string(field)
+}
+
+// parseRFC5424Timestamp parses an RFC 5424 TIMESTAMP field, which is
+// either NILVALUE or a FULL-DATE "T" FULL-TIME as defined by RFC 3339,
+// optionally with fractional seconds.
+func parseRFC5424Timestamp(s string) (time.Time, error) {
+	if s == "-" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("rfc5424: invalid timestamp %q", s)
+}
+
+// parseStructuredData parses the STRUCTURED-DATA field at the start of
+// rest, which is either NILVALUE or one or more back-to-back
+// "[SD-ID (SP SD-PARAM)*]" elements, SD-PARAM being NAME="VALUE" with
+// \], \" and \\ escapes inside VALUE. It returns the parsed data (nil for
+// NILVALUE) and whatever of rest follows it (with the single separating
+// space before MSG, if any, already consumed).
+func parseStructuredData(rest []byte) (map[string]map[string]string, []byte, error) {
+	if len(rest) > 0 && rest[0] == '-' {
+		rest = rest[1:]
+		if len(rest) > 0 && rest[0] == ' ' {
+			rest = rest[1:]
+		}
+		return nil, rest, nil
+	}
+
+	var sd map[string]map[string]string
+	for len(rest) > 0 && rest[0] == '[' {
+		rest = rest[1:]
+		idEnd := bytes.IndexAny(rest, " ]")
+		if idEnd == -1 {
+			return nil, nil, errors.New("rfc5424: malformed structured data element")
+		}
+		sdID := string(rest[:idEnd])
+		rest = rest[idEnd:]
+
+		params := make(map[string]string)
+		for len(rest) > 0 && rest[0] == ' ' {
+			rest = rest[1:]
+			eq := bytes.IndexByte(rest, '=')
+			if eq == -1 {
+				return nil, nil, errors.New("rfc5424: malformed SD-PARAM")
+			}
+			name := string(rest[:eq])
+			rest = rest[eq+1:]
+			if len(rest) == 0 || rest[0] != '"' {
+				return nil, nil, errors.New("rfc5424: SD-PARAM value must be quoted")
+			}
+			rest = rest[1:]
+
+			var val bytes.Buffer
+			for len(rest) > 0 && rest[0] != '"' {
+				if rest[0] == '\\' && len(rest) > 1 && (rest[1] == ']' || rest[1] == '"' || rest[1] == '\\') {
+					val.WriteByte(rest[1])
+					rest = rest[2:]
+					continue
+				}
+				val.WriteByte(rest[0])
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return nil, nil, errors.New("rfc5424: unterminated SD-PARAM value")
+			}
+			rest = rest[1:] // closing quote
+			params[name] = val.String()
+		}
+
+		if len(rest) == 0 || rest[0] != ']' {
+			return nil, nil, errors.New("rfc5424: unterminated structured data element")
+		}
+		rest = rest[1:]
+
+		if sd == nil {
+			sd = make(map[string]map[string]string)
+		}
+		sd[sdID] = params
+	}
+
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return sd, rest, nil
+}
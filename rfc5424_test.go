@@ -0,0 +1,124 @@
+package syslog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRFC5424Full(t *testing.T) {
+	pkt := []byte("1 2023-08-24T14:15:00.003Z mymachine.example.com appname 1234 ID47 " +
+		`[exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"] ` +
+		"\xEF\xBB\xBFAn application event log entry")
+
+	m, err := parseRFC5424(pkt)
+	if err != nil {
+		t.Fatalf("parseRFC5424: %v", err)
+	}
+
+	if m.Version != 1 {
+		t.Errorf("Version = %d, want 1", m.Version)
+	}
+	wantTime, err := time.Parse(time.RFC3339Nano, "2023-08-24T14:15:00.003Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Timestamp.Equal(wantTime) {
+		t.Errorf("Timestamp = %v, want %v", m.Timestamp, wantTime)
+	}
+	if m.Hostname != "mymachine.example.com" {
+		t.Errorf("Hostname = %q", m.Hostname)
+	}
+	if m.AppName != "appname" {
+		t.Errorf("AppName = %q", m.AppName)
+	}
+	if m.Tag != "appname" {
+		t.Errorf("Tag = %q, want legacy Tag populated from APP-NAME", m.Tag)
+	}
+	if m.ProcID != "1234" {
+		t.Errorf("ProcID = %q", m.ProcID)
+	}
+	if m.MsgID != "ID47" {
+		t.Errorf("MsgID = %q", m.MsgID)
+	}
+	wantSD := map[string]map[string]string{
+		"exampleSDID@32473": {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+	}
+	if !reflect.DeepEqual(m.StructuredData, wantSD) {
+		t.Errorf("StructuredData = %#v, want %#v", m.StructuredData, wantSD)
+	}
+	// The BOM before MSG must be stripped.
+	if m.Content != "An application event log entry" {
+		t.Errorf("Content = %q", m.Content)
+	}
+}
+
+func TestParseRFC5424NilValues(t *testing.T) {
+	m, err := parseRFC5424([]byte("1 - - - - - - msg"))
+	if err != nil {
+		t.Fatalf("parseRFC5424: %v", err)
+	}
+	if !m.Timestamp.IsZero() {
+		t.Errorf("Timestamp = %v, want zero value for NILVALUE", m.Timestamp)
+	}
+	if m.Hostname != "" || m.AppName != "" || m.ProcID != "" || m.MsgID != "" {
+		t.Errorf("expected all NILVALUE fields empty, got %+v", m)
+	}
+	if m.StructuredData != nil {
+		t.Errorf("StructuredData = %#v, want nil for NILVALUE", m.StructuredData)
+	}
+	if m.Content != "msg" {
+		t.Errorf("Content = %q", m.Content)
+	}
+}
+
+func TestParseRFC5424MultipleStructuredDataElements(t *testing.T) {
+	m, err := parseRFC5424([]byte(`1 - - - - - [id1 a="1"][id2 b="2"] content`))
+	if err != nil {
+		t.Fatalf("parseRFC5424: %v", err)
+	}
+	want := map[string]map[string]string{
+		"id1": {"a": "1"},
+		"id2": {"b": "2"},
+	}
+	if !reflect.DeepEqual(m.StructuredData, want) {
+		t.Errorf("StructuredData = %#v, want %#v", m.StructuredData, want)
+	}
+	if m.Content != "content" {
+		t.Errorf("Content = %q", m.Content)
+	}
+}
+
+func TestParseRFC5424EscapedStructuredDataValue(t *testing.T) {
+	m, err := parseRFC5424([]byte(`1 - - - - - [id x="a\]b\"c\\d"] content`))
+	if err != nil {
+		t.Fatalf("parseRFC5424: %v", err)
+	}
+	want := `a]b"c\d`
+	got := m.StructuredData["id"]["x"]
+	if got != want {
+		t.Errorf("StructuredData[id][x] = %q, want %q", got, want)
+	}
+}
+
+func TestParseRFC5424TruncatedHeader(t *testing.T) {
+	if _, err := parseRFC5424([]byte("1 2023-08-24T14:15:00Z host")); err == nil {
+		t.Fatal("expected error for truncated header, got nil")
+	}
+}
+
+func TestLooksLikeRFC5424(t *testing.T) {
+	cases := []struct {
+		pkt  string
+		want bool
+	}{
+		{"1 2023-08-24T14:15:00Z host app - - - msg", true},
+		{"Jan  1 00:00:00 host app: msg", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := looksLikeRFC5424([]byte(c.pkt)); got != c.want {
+			t.Errorf("looksLikeRFC5424(%q) = %v, want %v", c.pkt, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxRFC6587FrameSize bounds both the octet-counted MSG-LEN a peer may
+// claim and the length-prefix digits we'll buffer looking for its
+// trailing space, so a malicious or broken client can't force an
+// unbounded allocation or read per connection. It's sized the same as
+// udpReadBufferSize: nothing this library parses is expected to exceed it.
+const maxRFC6587FrameSize = udpReadBufferSize
+
+// maxMsgLenDigits is enough digits to spell maxRFC6587FrameSize, plus
+// slack; it bounds the length-prefix read so a client that never sends
+// the separating space can't accumulate an unbounded buffer.
+const maxMsgLenDigits = 10
+
+// readRFC6587Frame reads one SYSLOG-FRAME from r, auto-detecting which of
+// the two RFC 6587 transport framings is in use from the first byte: if
+// it's an ASCII digit the frame is octet-counted ("MSG-LEN SP
+// SYSLOG-MSG"), otherwise it's non-transparent framing, delimited by LF.
+func readRFC6587Frame(r *bufio.Reader) ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if b[0] >= '0' && b[0] <= '9' {
+		lenField, err := readMsgLenField(r)
+		if err != nil {
+			return nil, err
+		}
+		msgLen, err := strconv.Atoi(lenField[:len(lenField)-1])
+		if err != nil {
+			return nil, fmt.Errorf("rfc6587: invalid MSG-LEN %q", lenField)
+		}
+		if msgLen > maxRFC6587FrameSize {
+			return nil, fmt.Errorf("rfc6587: MSG-LEN %d exceeds maximum frame size %d", msgLen, maxRFC6587FrameSize)
+		}
+		frame := make([]byte, msgLen)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	}
+
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\n"), nil
+}
+
+// readMsgLenField reads the "MSG-LEN " prefix of an octet-counted frame a
+// byte at a time, so a peer that never sends the separating space can't
+// make us buffer more than maxMsgLenDigits digits.
+func readMsgLenField(r *bufio.Reader) (string, error) {
+	var field []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		field = append(field, c)
+		if c == ' ' {
+			return string(field), nil
+		}
+		if len(field) > maxMsgLenDigits {
+			return "", fmt.Errorf("rfc6587: MSG-LEN field exceeds %d digits", maxMsgLenDigits)
+		}
+	}
+}
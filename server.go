@@ -1,9 +1,12 @@
-// Syslog server library. It is based on RFC 3164 so it doesn't parse properly
-// packets with new header format (described in RFC 5424).
+// Syslog server library. It parses both the legacy RFC 3164 header and the
+// RFC 5424 header, including STRUCTURED-DATA.
 package syslog
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
@@ -13,12 +16,19 @@ import (
 	"unicode"
 )
 
+// udpReadBufferSize is sized for the largest message RFC 5424 allows over
+// a connectionless transport; RFC 3164's 1024-byte assumption truncates
+// legitimate RFC 5424 traffic silently.
+const udpReadBufferSize = 64 * 1024
+
 type Server struct {
-	conns    []net.Listener
-	handlers []Handler
-	shutdown bool
-	tagrunes map[rune]bool
-	l        FatalLogger
+	packetConns []net.PacketConn
+	listeners   []net.Listener
+	handlers    []Handler
+	shutdown    bool
+	tagrunes    map[rune]bool
+	l           FatalLogger
+	metrics     serverMetrics
 }
 
 //  NewServer creates idle server
@@ -40,42 +50,52 @@ func (s *Server) AddHandler(h Handler) {
 	s.handlers = append(s.handlers, h)
 }
 
-// Listen starts gorutine that receives syslog messages on specified address.
-// addr can be a path (for unix domain sockets) or host:port (for UDP).
+// Listen starts a goroutine that receives syslog messages on the specified
+// address. proto is one of "udp", "tcp", "unix" (a unix domain stream
+// socket, SOCK_STREAM) or "unixgram" (a unix domain datagram socket,
+// SOCK_DGRAM). addr is a path for "unix"/"unixgram" or host:port otherwise.
+//
+// UDP and unixgram connections are read with ReadFrom, one goroutine per
+// listener. TCP and unix stream connections are Accept()-ed in a loop, with
+// one goroutine per accepted connection decoding RFC 6587 frames from it.
 func (s *Server) Listen(addr string, proto string) error {
-	var c net.Listener
-	if proto == "udp" {
-		c, err := net.Listen("udp", addr)
-		if err != nil {
-			return err
-		}
-	} else if proto == "tcp" {
-		c, err := net.Listen("tcp", addr)
+	switch proto {
+	case "udp", "unixgram":
+		c, err := net.ListenPacket(proto, addr)
 		if err != nil {
 			return err
 		}
-	} else {
-		c, err := net.Listen("unix", addr)
+		s.packetConns = append(s.packetConns, c)
+		go s.receivePacketConn(c)
+	case "tcp", "unix":
+		l, err := net.Listen(proto, addr)
 		if err != nil {
 			return err
 		}
+		s.listeners = append(s.listeners, l)
+		go s.acceptLoop(l)
+	default:
+		return fmt.Errorf("syslog: unknown protocol %q", proto)
 	}
-	s.conns = append(s.conns, c)
-	go s.receiver(c)
 	return nil
 }
 
 // Shutdown stops server.
 func (s *Server) Shutdown() {
 	s.shutdown = true
-	for _, c := range s.conns {
-		err := c.Close()
-		if err != nil {
+	for _, c := range s.packetConns {
+		if err := c.Close(); err != nil {
+			s.l.Fatalln(err)
+		}
+	}
+	for _, l := range s.listeners {
+		if err := l.Close(); err != nil {
 			s.l.Fatalln(err)
 		}
 	}
 	s.passToHandlers(nil)
-	s.conns = nil
+	s.packetConns = nil
+	s.listeners = nil
 	s.handlers = nil
 }
 
@@ -95,100 +115,171 @@ func (s *Server) AddAllowedRunes(allowed string) {
 }
 
 func (s *Server) passToHandlers(m *Message) {
+	if m != nil {
+		s.addReceived(1)
+	}
 	for _, h := range s.handlers {
-		m = h.Handle(m)
+		m = s.safeHandle(h, m)
 		if m == nil {
 			break
 		}
 	}
 }
 
-func (s *Server) receiver(c net.Listener) {
-	buf := make([]byte, 1024)
+// safeHandle calls h.Handle(m), recovering from and logging any panic so
+// that one misbehaving handler can't take down the receiver goroutine that
+// drives it. A recovered panic is treated as a no-op: the chain continues
+// with m unchanged.
+func (s *Server) safeHandle(h Handler, m *Message) (result *Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.addHandlerPanics(1)
+			log.Printf("handler panic recovered: %v", r)
+			result = m
+		}
+	}()
+	return h.Handle(m)
+}
+
+// receivePacketConn reads whole datagrams from a UDP or unixgram listener
+// and dispatches each one as a single message.
+func (s *Server) receivePacketConn(c net.PacketConn) {
+	buf := make([]byte, udpReadBufferSize)
 	for {
-		conn, err := c.Accept()
-		n, err := conn.Read(buf)
+		n, addr, err := c.ReadFrom(buf)
 		if err != nil {
 			if !s.shutdown {
 				s.l.Fatalln("Read error:", err)
 			}
 			return
 		}
-		pkt := buf[:n]
-
-		m := new(Message)
-		m.Source = conn.RemoteAddr()
-		m.Time = time.Now()
-
-		// Parse priority (if exists)
-		prio := 13 // default priority
-		hasPrio := false
-		if pkt[0] == '<' {
-			n = 1 + bytes.IndexByte(pkt[1:], '>')
-			if n > 1 && n < 5 {
-				p, err := strconv.Atoi(string(pkt[1:n]))
-				if err == nil && p >= 0 {
-					hasPrio = true
-					prio = p
-					pkt = pkt[n+1:]
-				}
-			}
+		pkt := make([]byte, n)
+		copy(pkt, buf[:n])
+		if m := s.parsePacket(pkt, addr); m != nil {
+			s.passToHandlers(m)
 		}
-		m.Severity = Severity(prio & 0x07)
-		m.Facility = Facility(prio >> 3)
-
-		hostnameOffset := 0
-		ts := time.Now()
-
-		// Parse header (if exists)
-		if hasPrio && len(pkt) >= 26 && pkt[25] == ' ' && pkt[15] != ' ' {
-			// OK, it looks like we're dealing with a RFC 5424-style packet
-			ts, err := time.Parse(time.RFC3339, string(pkt[:25]))
-			if err == nil && !ts.IsZero() {
-				// Time parsed correctly.  This is most certainly a RFC 5424-style packet.
-				// Hostname starts at pkt[26]
-				hostnameOffset = 26
+	}
+}
+
+// acceptLoop accepts connections from a TCP or unix stream listener and
+// spawns one streamReceiver goroutine per connection.
+func (s *Server) acceptLoop(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !s.shutdown {
+				s.l.Fatalln("Accept error:", err)
 			}
-		} else if hasPrio && len(pkt) >= 16 && pkt[15] == ' ' {
-			// Looks like we're dealing with a RFC 3164-style packet
-			layout := "Jan _2 15:04:05"
-			ts, err := time.Parse(layout, string(pkt[:15]))
-			if err == nil && !ts.IsZero() {
-				// Time parsed correctly.   This is most certainly a RFC 3164-style packet.
-				hostnameOffset = 16
+			return
+		}
+		go s.streamReceiver(conn)
+	}
+}
+
+// streamReceiver decodes RFC 6587 frames from a single stream connection
+// until it errors out or is closed, dispatching each frame as one message.
+//
+// Read errors here are per-connection (a client disconnecting uncleanly,
+// sending a malformed frame, and so on) and are only ever logged, never
+// passed to FatalLogger: one bad connection must not take down every
+// other connection's goroutine, let alone the whole process.
+func (s *Server) streamReceiver(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		frame, err := readRFC6587Frame(r)
+		if err != nil {
+			if err != io.EOF && !s.shutdown {
+				log.Printf("Read error from %s: %v", conn.RemoteAddr(), err)
 			}
+			return
+		}
+		if m := s.parsePacket(frame, conn.RemoteAddr()); m != nil {
+			s.passToHandlers(m)
 		}
+	}
+}
+
+// parsePacket parses a single message (a UDP/unixgram datagram, or one
+// RFC 6587 frame from a stream) received from source.
+func (s *Server) parsePacket(pkt []byte, source net.Addr) *Message {
+	m := new(Message)
+	m.Source = source
+	m.Time = time.Now()
 
-		if hostnameOffset == 0 {
-			log.Printf("Packet did not parse correctly:\n%v\n", string(pkt[:]))
-		} else {
-			n = hostnameOffset + bytes.IndexByte(pkt[hostnameOffset:], ' ')
-			if n != hostnameOffset-1 {
-				m.Timestamp = ts
-				m.Hostname = string(pkt[hostnameOffset:n])
+	// Parse priority (if exists)
+	prio := 13 // default priority
+	hasPrio := false
+	if len(pkt) > 0 && pkt[0] == '<' {
+		n := 1 + bytes.IndexByte(pkt[1:], '>')
+		if n > 1 && n < 5 {
+			p, err := strconv.Atoi(string(pkt[1:n]))
+			if err == nil && p >= 0 {
+				hasPrio = true
+				prio = p
 				pkt = pkt[n+1:]
 			}
 		}
-		_ = hostnameOffset
-
-		// Parse msg part
-		msg := string(bytes.TrimRightFunc(pkt, isNulCrLf))
-		n = strings.IndexFunc(msg, s.isNotAlnum)
-		if n != -1 {
-			m.Tag = msg[:n]
-			m.Content = msg[n:]
-		} else {
-			m.Content = msg
+	}
+	m.Severity = Severity(prio & 0x07)
+	m.Facility = Facility(prio >> 3)
+
+	if hasPrio && looksLikeRFC5424(pkt) {
+		rm, err := parseRFC5424(pkt)
+		if err == nil {
+			rm.Source = m.Source
+			rm.Time = m.Time
+			rm.Severity = m.Severity
+			rm.Facility = m.Facility
+			return rm
 		}
-		msg = strings.TrimFunc(msg, unicode.IsSpace)
-		n = strings.IndexFunc(msg, unicode.IsSpace)
-		if n != -1 {
-			m.Tag1 = msg[:n]
-			m.Content1 = strings.TrimLeftFunc(msg[n+1:], unicode.IsSpace)
-		} else {
-			m.Content1 = msg
+		// A digit-led but malformed/truncated header isn't necessarily
+		// RFC 5424 after all; fall through to the RFC 3164-style parse
+		// below instead of discarding the message outright.
+		log.Printf("Packet looked like RFC 5424 but failed to parse: %v\n%v\n", err, string(pkt))
+	}
+
+	hostnameOffset := 0
+	ts := time.Now()
+
+	// Parse header (if exists)
+	if hasPrio && len(pkt) >= 16 && pkt[15] == ' ' {
+		// Looks like we're dealing with a RFC 3164-style packet
+		layout := "Jan _2 15:04:05"
+		parsed, err := time.Parse(layout, string(pkt[:15]))
+		if err == nil && !parsed.IsZero() {
+			// Time parsed correctly.   This is most certainly a RFC 3164-style packet.
+			ts = parsed
+			hostnameOffset = 16
 		}
+	}
 
-		s.passToHandlers(m)
+	if hostnameOffset == 0 {
+		log.Printf("Packet did not parse correctly:\n%v\n", string(pkt))
+	} else {
+		n := hostnameOffset + bytes.IndexByte(pkt[hostnameOffset:], ' ')
+		if n != hostnameOffset-1 {
+			m.Timestamp = ts
+			m.Hostname = string(pkt[hostnameOffset:n])
+			pkt = pkt[n+1:]
+		}
 	}
+
+	// Parse msg part
+	msg := string(bytes.TrimRightFunc(pkt, isNulCrLf))
+	if n := strings.IndexFunc(msg, s.isNotAlnum); n != -1 {
+		m.Tag = msg[:n]
+		m.Content = msg[n:]
+	} else {
+		m.Content = msg
+	}
+	msg = strings.TrimFunc(msg, unicode.IsSpace)
+	if n := strings.IndexFunc(msg, unicode.IsSpace); n != -1 {
+		m.Tag1 = msg[:n]
+		m.Content1 = strings.TrimLeftFunc(msg[n+1:], unicode.IsSpace)
+	} else {
+		m.Content1 = msg
+	}
+
+	return m
 }
@@ -0,0 +1,171 @@
+package syslog
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParsePacketRFC3164(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+
+	m := s.parsePacket([]byte("<34>Jan 12 06:30:00 mymachine su: failure"), nil)
+	if m.Facility != Facility(4) || m.Severity != Severity(2) {
+		t.Errorf("Facility/Severity = %v/%v, want 4/2", m.Facility, m.Severity)
+	}
+	if m.Hostname != "mymachine" {
+		t.Errorf("Hostname = %q", m.Hostname)
+	}
+	if m.Tag != "su" || m.Content != ": failure" {
+		t.Errorf("Tag/Content = %q/%q", m.Tag, m.Content)
+	}
+}
+
+func TestParsePacketFallsBackWhenRFC5424HeaderMalformed(t *testing.T) {
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+
+	// Digit-led (looks like an RFC 5424 VERSION) but truncated, so
+	// parseRFC5424 fails; the legacy Tag/Content split must still run
+	// instead of handing back an all-empty Message.
+	m := s.parsePacket([]byte("<14>1 not-a-valid-header"), nil)
+	if m.Content == "" && m.Tag == "" {
+		t.Fatalf("got an all-empty Message for a malformed digit-led packet: %+v", m)
+	}
+}
+
+func TestReceivePacketConnDispatchesDatagrams(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	s.AddHandler(rec)
+	s.packetConns = append(s.packetConns, pc)
+	go s.receivePacketConn(pc)
+
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("<34>Jan 12 06:30:00 mymachine su: failure")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(rec.messages()) == 1 }, "UDP datagram delivered to the handler chain")
+	s.shutdown = true
+	pc.Close()
+}
+
+func TestAcceptLoopSpawnsOneGoroutinePerConnection(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	s.AddHandler(rec)
+	s.listeners = append(s.listeners, l)
+	go s.acceptLoop(l)
+
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Write([]byte("<34>Jan 12 06:30:00 mymachine su: failure\n")); err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+
+	waitFor(t, func() bool { return len(rec.messages()) == 2 }, "both connections delivered their message")
+	s.shutdown = true
+	l.Close()
+}
+
+func TestStreamReceiverOctetCountedFraming(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	s.AddHandler(rec)
+	s.listeners = append(s.listeners, l)
+	go s.acceptLoop(l)
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	msg1 := "<34>Jan 12 06:30:00 mymachine su: one"
+	msg2 := "<34>Jan 12 06:30:00 mymachine su: two"
+	frame := strconv.Itoa(len(msg1)) + " " + msg1 + strconv.Itoa(len(msg2)) + " " + msg2
+	if _, err := conn.Write([]byte(frame)); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(rec.messages()) == 2 }, "both octet-counted frames delivered")
+
+	got := rec.messages()
+	if got[0].Content != ": one" || got[1].Content != ": two" {
+		t.Errorf("got contents %q, %q", got[0].Content, got[1].Content)
+	}
+
+	s.shutdown = true
+	l.Close()
+}
+
+func TestReadRFC6587FrameRejectsOversizedLength(t *testing.T) {
+	r, w := net.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	// 5 digits, well inside the digit cap, but above maxRFC6587FrameSize.
+	go w.Write([]byte("99999 "))
+
+	br := bufio.NewReader(r)
+	if _, err := readRFC6587Frame(br); err == nil {
+		t.Fatal("expected an error for an oversized MSG-LEN, got nil")
+	}
+}
+
+func TestReadRFC6587FrameRejectsUnboundedLengthDigits(t *testing.T) {
+	r, w := net.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Write([]byte("1234567890123456789012345678901234567890 "))
+	}()
+
+	br := bufio.NewReader(r)
+	errc := make(chan error, 1)
+	go func() { _, err := readRFC6587Frame(br); errc <- err }()
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected an error for an unbounded MSG-LEN digit run, got nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("readRFC6587Frame blocked instead of bounding the digit run")
+	}
+	<-done
+}
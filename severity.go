@@ -0,0 +1,36 @@
+package syslog
+
+// Severity is the syslog severity, the 3 least significant bits of the
+// combined PRIVAL (PRI = Facility*8 + Severity).
+type Severity int
+
+const (
+	SeverityEmerg Severity = iota
+	SeverityAlert
+	SeverityCrit
+	SeverityErr
+	SeverityWarning
+	SeverityNotice
+	SeverityInfo
+	SeverityDebug
+)
+
+var severityNames = map[Severity]string{
+	SeverityEmerg:   "emerg",
+	SeverityAlert:   "alert",
+	SeverityCrit:    "crit",
+	SeverityErr:     "err",
+	SeverityWarning: "warning",
+	SeverityNotice:  "notice",
+	SeverityInfo:    "info",
+	SeverityDebug:   "debug",
+}
+
+// String returns the conventional short name for s, or "unknown" if s isn't
+// one of the defined severities.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
@@ -0,0 +1,83 @@
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+)
+
+// ListenTLS starts a goroutine that accepts TLS connections on addr (RFC
+// 5425) and decodes RFC 6587 frames from each, same as a plain TCP
+// listener started via Listen. cfg controls the TLS handshake; set
+// ClientAuth to tls.RequireAndVerifyClientCert to require client
+// certificates, whose subject is then exposed on Message.PeerIdentity so
+// handlers can authorize per-sender.
+//
+// A connection that fails its handshake is logged but does not affect the
+// accept loop or any other connection; FatalLogger is reserved for
+// listener-level failures (see acceptTLSLoop).
+func (s *Server) ListenTLS(addr string, cfg *tls.Config) error {
+	inner, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	l := tls.NewListener(inner, cfg)
+	s.listeners = append(s.listeners, l)
+	go s.acceptTLSLoop(l)
+	return nil
+}
+
+func (s *Server) acceptTLSLoop(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !s.shutdown {
+				s.l.Fatalln("Accept error:", err)
+			}
+			return
+		}
+		tlsConn, ok := conn.(*tls.Conn)
+		if !ok {
+			conn.Close()
+			continue
+		}
+		go s.streamReceiverTLS(tlsConn)
+	}
+}
+
+// streamReceiverTLS handles a single accepted TLS connection. A failed
+// handshake or a subsequent read error is per-connection (a port scanner,
+// a client without a required cert, a dropped connection) and is only
+// ever logged, never passed to FatalLogger: it must not affect any other
+// connection, let alone the whole process.
+func (s *Server) streamReceiverTLS(conn *tls.Conn) {
+	defer conn.Close()
+	if err := conn.Handshake(); err != nil {
+		log.Printf("TLS handshake error from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	var peerIdentity string
+	if state := conn.ConnectionState(); len(state.VerifiedChains) > 0 && len(state.PeerCertificates) > 0 {
+		peerIdentity = state.PeerCertificates[0].Subject.String()
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		frame, err := readRFC6587Frame(r)
+		if err != nil {
+			if err != io.EOF && !s.shutdown {
+				log.Printf("Read error from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		m := s.parsePacket(frame, conn.RemoteAddr())
+		if m == nil {
+			continue
+		}
+		m.PeerIdentity = peerIdentity
+		s.passToHandlers(m)
+	}
+}
@@ -0,0 +1,137 @@
+package syslog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCert generates a throwaway self-signed certificate/key pair for cn,
+// suitable for both server and client use in these tests.
+func testCert(t *testing.T, cn string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestListenTLSDeliversMessageAndPeerIdentity(t *testing.T) {
+	serverCert := testCert(t, "syslog-server")
+	clientCert := testCert(t, "syslog-client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.Leaf)
+
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	s.AddHandler(rec)
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	if err := s.ListenTLS("127.0.0.1:0", cfg); err != nil {
+		t.Fatal(err)
+	}
+	addr := s.listeners[len(s.listeners)-1].Addr().String()
+
+	clientCfg := &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+	}
+	conn, err := tls.Dial("tcp", addr, clientCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("<34>Jan 12 06:30:00 mymachine su: failure\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(rec.messages()) == 1 }, "TLS connection delivered its message")
+
+	got := rec.messages()[0]
+	if got.PeerIdentity == "" {
+		t.Errorf("PeerIdentity = %q, want the client cert's subject", got.PeerIdentity)
+	}
+
+	s.shutdown = true
+}
+
+func TestListenTLSRejectsHandshakeFailureWithoutAffectingOtherConnections(t *testing.T) {
+	serverCert := testCert(t, "syslog-server")
+	clientCert := testCert(t, "syslog-client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.Leaf)
+
+	s := NewServer()
+	s.SetLogger(&testFatalLogger{t})
+	rec := &recordingHandler{}
+	s.AddHandler(rec)
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	if err := s.ListenTLS("127.0.0.1:0", cfg); err != nil {
+		t.Fatal(err)
+	}
+	addr := s.listeners[len(s.listeners)-1].Addr().String()
+
+	// Plain TCP dial with no TLS and no client cert: the handshake must
+	// fail and be logged, but must not reach FatalLogger or wedge the
+	// accept loop for the well-behaved connection that follows.
+	bad, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad.Write([]byte("not a TLS handshake"))
+	bad.Close()
+
+	clientCfg := &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+	}
+	good, err := tls.Dial("tcp", addr, clientCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer good.Close()
+	if _, err := good.Write([]byte("<34>Jan 12 06:30:00 mymachine su: failure\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(rec.messages()) == 1 }, "the well-behaved connection delivered its message despite the earlier handshake failure")
+
+	s.shutdown = true
+}